@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io/fs"
 	"io/ioutil"
@@ -16,15 +17,95 @@ import (
 )
 
 type configTarget struct {
-	SchemaFile   []string `json:"schema"`
-	TemplateFile string   `json:"template"`
-	OutputFile   string   `json:"output"`
+	// SchemaFile is a list of globs matching the `.graphql` documents (queries,
+	// mutations and fragments) to transform.
+	SchemaFile []string `json:"schema"`
+	// Schema is a list of globs matching the `.graphql` SDL files that describe
+	// the server's type system. When set, fields, arguments and variables are
+	// resolved against it and exposed to templates with real type information.
+	Schema []string `json:"graphqlSchema"`
+	// SchemaEndpoint is an alternative to Schema: a GraphQL HTTP endpoint that
+	// is queried with the standard introspection query to build the same type
+	// system. SchemaEndpoint takes priority over Schema if both are set.
+	SchemaEndpoint string `json:"schemaEndpoint"`
+	// PersistedQueries, when true, computes OperationText/OperationHash for every
+	// operation and fragment so templates can emit APQ manifests and `{id}`-based
+	// client stubs instead of the full query text.
+	PersistedQueries bool   `json:"persistedQueries"`
+	TemplateFile     string `json:"template"`
+	OutputFile       string `json:"output"`
 }
 
 type config struct {
 	Targets []configTarget `json:"targets"`
 }
 
+// resolveGlobFiles expands a list of globs (each potentially containing `*`)
+// into the deduplicated list of files on disk that they match. Files are
+// listed glob by glob, in the order the globs are given, and in lexical walk
+// order within each glob; the list as a whole is not sorted.
+func resolveGlobFiles(globs []string) ([]string, error) {
+	visitedFiles := make(map[string]bool, 100)
+	matchedFiles := make([]string, 0, len(globs))
+
+	for _, fileGlob := range globs {
+		if strings.HasPrefix(fileGlob, "./") {
+			fileGlob = fileGlob[2:]
+		}
+
+		absGlob, err := filepath.Abs(fileGlob)
+		if err != nil {
+			return nil, err
+		}
+
+		walkPath := filepath.Dir(absGlob)
+		for strings.Contains(walkPath, "*") {
+			walkPath = filepath.Dir(walkPath)
+		}
+
+		err = fs.WalkDir(os.DirFS(walkPath), ".", func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			filePath = filepath.Join(walkPath, filePath)
+			if visitedFiles[filePath] {
+				return nil
+			}
+
+			if matched, _ := path.Match(absGlob, filePath); matched {
+				matchedFiles = append(matchedFiles, filePath)
+				visitedFiles[filePath] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return matchedFiles, nil
+}
+
+func loadTargetSchema(target configTarget) (*Schema, error) {
+	if target.SchemaEndpoint != "" {
+		return LoadSchemaFromEndpoint(target.SchemaEndpoint)
+	}
+	if len(target.Schema) == 0 {
+		return nil, nil
+	}
+
+	schemaFiles, err := resolveGlobFiles(target.Schema)
+	if err != nil {
+		return nil, err
+	}
+	return LoadSchema(schemaFiles)
+}
+
 func buildTargets(target configTarget) error {
 	buildStart := time.Now()
 
@@ -34,8 +115,12 @@ func buildTargets(target configTarget) error {
 	}
 
 	tmpl, err := template.New(target.OutputFile).Funcs(map[string]any{
-		"camelCase":  camelCase,
-		"pascalCase": pascalCase,
+		"camelCase":      camelCase,
+		"pascalCase":     pascalCase,
+		"sha256":         hashOperationText,
+		"isConnection":   isConnection,
+		"nodeType":       nodeType,
+		"pageInfoFields": pageInfoFields,
 	}).Parse(string(templateStr))
 	if err != nil {
 		return err
@@ -43,59 +128,43 @@ func buildTargets(target configTarget) error {
 
 	fmt.Printf("\nBuilding %s using %s\n", target.OutputFile, target.TemplateFile)
 
+	schema, err := loadTargetSchema(target)
+	if err != nil {
+		return err
+	}
+
 	templateData := TemplateData{
-		Fragments: make([]Fragment, 0),
-		Queries:   make([]Operation, 0),
-		Mutations: make([]Operation, 0),
+		Fragments:     make([]Fragment, 0),
+		Queries:       make([]Operation, 0),
+		Mutations:     make([]Operation, 0),
+		Subscriptions: make([]Operation, 0),
 	}
-	visitedFiles := make(map[string]bool, 100)
 
-	for _, schemaFileGlob := range target.SchemaFile {
-		if strings.HasPrefix(schemaFileGlob, "./") {
-			schemaFileGlob = schemaFileGlob[2:]
-		}
+	schemaFiles, err := resolveGlobFiles(target.SchemaFile)
+	if err != nil {
+		return err
+	}
 
-		absGlob, err := filepath.Abs(schemaFileGlob)
+	sources := make(map[string]string, len(schemaFiles))
+	for _, schemaFilePath := range schemaFiles {
+		source, err := ioutil.ReadFile(schemaFilePath)
 		if err != nil {
 			return err
 		}
+		sources[schemaFilePath] = string(source)
+	}
 
-		walkPath := filepath.Dir(absGlob)
-		for strings.Contains(walkPath, "*") {
-			walkPath = filepath.Dir(walkPath)
+	if validationErrs := validateDocuments(schema, sources); len(validationErrs) > 0 {
+		for _, validationErr := range validationErrs {
+			fmt.Fprintln(os.Stderr, validationErr.String())
 		}
+		return fmt.Errorf("%d validation error(s) found while building %s", len(validationErrs), target.OutputFile)
+	}
 
-		err = fs.WalkDir(os.DirFS(walkPath), ".", func(schemaFilePath string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-
-			if d.IsDir() {
-				return nil
-			}
-
-			schemaFilePath = filepath.Join(walkPath, schemaFilePath)
-			if _, ok := visitedFiles[schemaFilePath]; ok {
-				return nil
-			}
-
-			if matched, _ := path.Match(absGlob, schemaFilePath); matched {
-				fmt.Printf(" > adding: %s\n", schemaFilePath)
-
-				schema, err := ioutil.ReadFile(schemaFilePath)
-				if err != nil {
-					return err
-				}
-
-				err = transformGraphql(&templateData, string(schema))
-				if err != nil {
-					return err
-				}
+	for _, schemaFilePath := range schemaFiles {
+		fmt.Printf(" > adding: %s\n", schemaFilePath)
 
-				visitedFiles[schemaFilePath] = true
-			}
-			return nil
-		})
+		err = transformGraphql(&templateData, schema, sources[schemaFilePath])
 		if err != nil {
 			return err
 		}
@@ -105,6 +174,10 @@ func buildTargets(target configTarget) error {
 		return len(templateData.Fragments[leftIdx].FragmentDependencies) < len(templateData.Fragments[rightIdx].FragmentDependencies)
 	})
 
+	if target.PersistedQueries {
+		annotatePersistedQueries(&templateData)
+	}
+
 	fd, err := os.Create(target.OutputFile)
 	if err != nil {
 		return err
@@ -166,17 +239,28 @@ func pascalCase(str string) string {
 	return strings.Join(words, "")
 }
 
-func main() {
-	confBuf, err := ioutil.ReadFile("./graphql-transform.json")
+// loadConfig reads and parses the graphql-transform.json config at path.
+func loadConfig(path string) (config, error) {
+	confBuf, err := ioutil.ReadFile(path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to read config from graphql-transform.json: %s\n", err.Error())
-		os.Exit(1)
+		return config{}, fmt.Errorf("failed to read config from %s: %w", path, err)
 	}
 
 	conf := config{}
-	err = json.Unmarshal(confBuf, &conf)
+	if err := json.Unmarshal(confBuf, &conf); err != nil {
+		return config{}, fmt.Errorf("failed to parse config in %s: %w", path, err)
+	}
+	return conf, nil
+}
+
+func main() {
+	watch := flag.Bool("watch", false, "watch schema, template and config files and rebuild targets as they change")
+	flag.Parse()
+
+	configPath := "./graphql-transform.json"
+	conf, err := loadConfig(configPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to parse config in graphql-transform.json: %s\n", err.Error())
+		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
 
@@ -186,4 +270,11 @@ func main() {
 			os.Exit(1)
 		}
 	}
+
+	if *watch {
+		if err := watchTargets(configPath, conf.Targets); err != nil {
+			fmt.Fprintf(os.Stderr, "Watch failed: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}
 }
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// printFieldArguments renders a field or directive's arguments as `(a: 1, b: 2)`,
+// sorted alphabetically by name so the same logical selection always canonicalizes
+// to the same text regardless of source ordering.
+func printFieldArguments(args []FieldArgument) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	sorted := make([]FieldArgument, len(args))
+	copy(sorted, args)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	parts := make([]string, 0, len(sorted))
+	for _, arg := range sorted {
+		parts = append(parts, fmt.Sprintf("%s: %s", arg.Name, arg.Value))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, ", "))
+}
+
+func printDirectives(directives []Directive) string {
+	if len(directives) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(directives))
+	for _, directive := range directives {
+		parts = append(parts, fmt.Sprintf("@%s%s", directive.Name, printFieldArguments(directive.Arguments)))
+	}
+	return " " + strings.Join(parts, " ")
+}
+
+func printSelectionSet(fields []GraphqlField) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, printField(field))
+	}
+	return fmt.Sprintf(" { %s }", strings.Join(parts, " "))
+}
+
+func printField(field GraphqlField) string {
+	if field.IsSpread {
+		if field.Name != "" {
+			return fmt.Sprintf("...%s%s", field.Name, printDirectives(field.Directives))
+		}
+		return fmt.Sprintf("... on %s%s%s", field.SourceType, printDirectives(field.Directives), printSelectionSet(field.SubFields))
+	}
+	return fmt.Sprintf("%s%s%s%s", field.Name, printFieldArguments(field.Arguments), printDirectives(field.Directives), printSelectionSet(field.SubFields))
+}
+
+func printVariableDefinitions(variables []Variable) string {
+	if len(variables) == 0 {
+		return ""
+	}
+
+	sorted := make([]Variable, len(variables))
+	copy(sorted, variables)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	parts := make([]string, 0, len(sorted))
+	for _, variable := range sorted {
+		def := fmt.Sprintf("$%s: %s", variable.Name, variable.Type)
+		if variable.DefaultValue != "" {
+			def += fmt.Sprintf(" = %s", variable.DefaultValue)
+		}
+		parts = append(parts, def)
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, ", "))
+}
+
+// gatherTransitiveFragments appends every fragment referenced - directly or
+// transitively - by fields onto order, in dependency-first (topological) order,
+// so each fragment in the result only depends on fragments before it.
+func gatherTransitiveFragments(fields []GraphqlField, fragmentsByName map[string]Fragment, visited map[string]bool, order *[]string) {
+	for _, field := range fields {
+		if field.IsSpread && field.Name != "" && !visited[field.Name] {
+			visited[field.Name] = true
+			if frag, ok := fragmentsByName[field.Name]; ok {
+				gatherTransitiveFragments(frag.Fields, fragmentsByName, visited, order)
+				*order = append(*order, field.Name)
+			}
+		}
+		if len(field.SubFields) > 0 {
+			gatherTransitiveFragments(field.SubFields, fragmentsByName, visited, order)
+		}
+	}
+}
+
+func printFragmentDefinition(frag Fragment) string {
+	return fmt.Sprintf("fragment %s on %s%s%s", frag.Name, frag.SourceType, printDirectives(frag.Directives), printSelectionSet(frag.Fields))
+}
+
+// canonicalOperationText renders an operation (or a fragment treated as its own
+// root) to a single, deterministic string: comments and source whitespace are
+// already gone by the time fields reach here, arguments and variables are sorted,
+// and every transitively-used fragment is inlined in dependency order.
+func canonicalOperationText(kind, name string, variables []Variable, directives []Directive, fields []GraphqlField, fragmentsByName map[string]Fragment) string {
+	var b strings.Builder
+	b.WriteString(kind)
+	if name != "" {
+		b.WriteString(" ")
+		b.WriteString(name)
+	}
+	b.WriteString(printVariableDefinitions(variables))
+	b.WriteString(printDirectives(directives))
+	b.WriteString(printSelectionSet(fields))
+
+	var order []string
+	gatherTransitiveFragments(fields, fragmentsByName, make(map[string]bool), &order)
+	for _, fragName := range order {
+		b.WriteString(" ")
+		b.WriteString(printFragmentDefinition(fragmentsByName[fragName]))
+	}
+
+	return b.String()
+}
+
+func hashOperationText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// annotatePersistedQueries computes the canonical OperationText/OperationHash for
+// every operation and fragment in templateData, for use in APQ manifests and
+// `{id}`-based client stubs.
+func annotatePersistedQueries(templateData *TemplateData) {
+	fragmentsByName := make(map[string]Fragment, len(templateData.Fragments))
+	for _, frag := range templateData.Fragments {
+		fragmentsByName[frag.Name] = frag
+	}
+
+	annotateOperations := func(kind string, ops []Operation) {
+		for i := range ops {
+			text := canonicalOperationText(kind, ops[i].Name, ops[i].Variables, ops[i].Directives, ops[i].Fields, fragmentsByName)
+			ops[i].OperationText = text
+			ops[i].OperationHash = hashOperationText(text)
+		}
+	}
+	annotateOperations("query", templateData.Queries)
+	annotateOperations("mutation", templateData.Mutations)
+	annotateOperations("subscription", templateData.Subscriptions)
+
+	for i := range templateData.Fragments {
+		frag := templateData.Fragments[i]
+
+		var order []string
+		gatherTransitiveFragments(frag.Fields, fragmentsByName, map[string]bool{frag.Name: true}, &order)
+
+		var b strings.Builder
+		b.WriteString(printFragmentDefinition(frag))
+		for _, depName := range order {
+			b.WriteString(" ")
+			b.WriteString(printFragmentDefinition(fragmentsByName[depName]))
+		}
+
+		templateData.Fragments[i].OperationText = b.String()
+		templateData.Fragments[i].OperationHash = hashOperationText(b.String())
+	}
+}
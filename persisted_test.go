@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestAnnotatePersistedQueriesIsStableAcrossFormatting(t *testing.T) {
+	first := TemplateData{}
+	if err := transformGraphql(&first, nil, `
+		fragment UserFields on User { id name }
+		query GetUser($id: ID!) { user(id: $id) { ... UserFields } }
+	`); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	annotatePersistedQueries(&first)
+
+	second := TemplateData{}
+	if err := transformGraphql(&second, nil, `
+		query GetUser($id: ID!) {
+			user(id: $id) {
+				... UserFields
+			}
+		}
+
+		fragment UserFields on User {
+			id
+			name
+		}
+	`); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	annotatePersistedQueries(&second)
+
+	if first.Queries[0].OperationHash != second.Queries[0].OperationHash {
+		t.Errorf("Expected identical hashes regardless of formatting, got %q and %q (%q vs %q)",
+			first.Queries[0].OperationHash, second.Queries[0].OperationHash,
+			first.Queries[0].OperationText, second.Queries[0].OperationText)
+	}
+}
+
+func TestAnnotatePersistedQueriesSortsArgumentsAndVariables(t *testing.T) {
+	templateData := TemplateData{}
+	if err := transformGraphql(&templateData, nil, `
+		query Search($limit: Int, $after: String) {
+			results(limit: $limit, after: $after) {
+				id
+			}
+		}
+	`); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	annotatePersistedQueries(&templateData)
+
+	want := `query Search($after: String, $limit: Int) { results(after: $after, limit: $limit) { id } }`
+	if got := templateData.Queries[0].OperationText; got != want {
+		t.Errorf("Expected canonical text %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import "strings"
+
+// isRelayConnectionField reports whether field looks like a Relay connection:
+// either its resolved type name ends in `Connection` and it selects
+// `edges { node }` and `pageInfo`, or it simply accepts one of the standard
+// cursor-pagination arguments.
+func isRelayConnectionField(field GraphqlField) bool {
+	if strings.HasSuffix(field.NamedType, "Connection") && hasEdgesNodeSelection(field.SubFields) && hasPageInfoSelection(field.SubFields) {
+		return true
+	}
+
+	for _, arg := range field.Arguments {
+		switch arg.Name {
+		case "first", "last", "after", "before":
+			return true
+		}
+	}
+	return false
+}
+
+func hasEdgesNodeSelection(fields []GraphqlField) bool {
+	for _, field := range fields {
+		if field.Name != "edges" {
+			continue
+		}
+		for _, sub := range field.SubFields {
+			if sub.Name == "node" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasPageInfoSelection(fields []GraphqlField) bool {
+	for _, field := range fields {
+		if field.Name == "pageInfo" {
+			return true
+		}
+	}
+	return false
+}
+
+func findEdgesNode(field GraphqlField) *GraphqlField {
+	for _, edges := range field.SubFields {
+		if edges.Name != "edges" {
+			continue
+		}
+		for i, node := range edges.SubFields {
+			if node.Name == "node" {
+				return &edges.SubFields[i]
+			}
+		}
+	}
+	return nil
+}
+
+// connectionNodeType returns the resolved type of `edges.node`, i.e. the type
+// of object a connection field paginates over.
+func connectionNodeType(field GraphqlField) string {
+	if node := findEdgesNode(field); node != nil {
+		return node.NamedType
+	}
+	return ""
+}
+
+// connectionNodes projects `edges { node { ... } }` down to the node's own
+// selected fields, so templates can iterate nodes directly instead of walking
+// the edges/node shape themselves.
+func connectionNodes(field GraphqlField) []GraphqlField {
+	if node := findEdgesNode(field); node != nil {
+		return node.SubFields
+	}
+	return nil
+}
+
+// pageInfoFields returns the fields selected under `pageInfo` on a connection
+// field. It's also registered as the `pageInfoFields` template function.
+func pageInfoFields(field GraphqlField) []GraphqlField {
+	for _, sub := range field.SubFields {
+		if sub.Name == "pageInfo" {
+			return sub.SubFields
+		}
+	}
+	return nil
+}
+
+// isConnection and nodeType mirror GraphqlField.IsConnection/NodeType as
+// template functions, for templates that want the pipeline form, e.g.
+// `{{ if isConnection . }}`.
+func isConnection(field GraphqlField) bool { return field.IsConnection }
+func nodeType(field GraphqlField) string   { return field.NodeType }
@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestTransformGraphqlDetectsRelayConnection(t *testing.T) {
+	schemaFile := writeTempSchema(t, `
+		type User {
+			id: ID!
+			name: String
+		}
+
+		type UserEdge {
+			cursor: String!
+			node: User
+		}
+
+		type PageInfo {
+			hasNextPage: Boolean!
+			endCursor: String
+		}
+
+		type UserConnection {
+			edges: [UserEdge!]!
+			pageInfo: PageInfo!
+		}
+
+		type Query {
+			users(first: Int, after: String): UserConnection
+		}
+	`)
+
+	schema, err := LoadSchema([]string{schemaFile})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	templateData := TemplateData{}
+	err = transformGraphql(&templateData, schema, `
+		query ListUsers {
+			users(first: 10) {
+				edges {
+					node {
+						id
+						name
+					}
+				}
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+			}
+		}
+	`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	usersField := templateData.Queries[0].Fields[0]
+	if !usersField.IsConnection {
+		t.Fatalf("Expected users field to be detected as a connection")
+	}
+	if usersField.NodeType != "User" {
+		t.Errorf("Expected node type User, got %s", usersField.NodeType)
+	}
+	if len(usersField.Nodes) != 2 || usersField.Nodes[0].Name != "id" || usersField.Nodes[1].Name != "name" {
+		t.Errorf("Expected Nodes to project edges.node's selected fields, got %+v", usersField.Nodes)
+	}
+
+	pageInfo := pageInfoFields(usersField)
+	if len(pageInfo) != 2 || pageInfo[0].Name != "hasNextPage" {
+		t.Errorf("Expected pageInfoFields to return pageInfo's selected fields, got %+v", pageInfo)
+	}
+}
@@ -0,0 +1,611 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// Type is implemented by every entry in a loaded Schema: the named kinds
+// (Scalar, Object, Interface, Union, Enum, InputObject) as well as the two
+// wrapping kinds (List, NonNull) that graphql-go's definition.go models the
+// same way.
+type Type interface {
+	// TypeName returns the name of the type. Wrapping types (List, NonNull)
+	// delegate to the type they wrap.
+	TypeName() string
+	// TypeKind returns one of "SCALAR", "OBJECT", "INTERFACE", "UNION",
+	// "ENUM", "INPUT_OBJECT", "LIST" or "NON_NULL".
+	TypeKind() string
+}
+
+// Scalar represents a built-in or custom scalar type, e.g. `String` or `DateTime`.
+type Scalar struct {
+	Name string
+}
+
+func (s *Scalar) TypeName() string { return s.Name }
+func (s *Scalar) TypeKind() string { return "SCALAR" }
+
+// FieldDefinition represents a single field on an Object or Interface type.
+type FieldDefinition struct {
+	Name string
+	Type Type
+	Args []*InputFieldDefinition
+}
+
+// InputFieldDefinition represents a field argument or an input object field.
+type InputFieldDefinition struct {
+	Name         string
+	Type         Type
+	DefaultValue string
+}
+
+// Object represents an `type X { ... }` definition.
+type Object struct {
+	Name       string
+	Interfaces []string
+	Fields     map[string]*FieldDefinition
+}
+
+func (o *Object) TypeName() string { return o.Name }
+func (o *Object) TypeKind() string { return "OBJECT" }
+
+// Interface represents an `interface X { ... }` definition.
+type Interface struct {
+	Name   string
+	Fields map[string]*FieldDefinition
+}
+
+func (i *Interface) TypeName() string { return i.Name }
+func (i *Interface) TypeKind() string { return "INTERFACE" }
+
+// Union represents a `union X = A | B` definition.
+type Union struct {
+	Name  string
+	Types []string
+}
+
+func (u *Union) TypeName() string { return u.Name }
+func (u *Union) TypeKind() string { return "UNION" }
+
+// Enum represents an `enum X { ... }` definition.
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+func (e *Enum) TypeName() string { return e.Name }
+func (e *Enum) TypeKind() string { return "ENUM" }
+
+// InputObject represents an `input X { ... }` definition.
+type InputObject struct {
+	Name   string
+	Fields map[string]*InputFieldDefinition
+}
+
+func (o *InputObject) TypeName() string { return o.Name }
+func (o *InputObject) TypeKind() string { return "INPUT_OBJECT" }
+
+// List represents a `[OfType]` wrapping type.
+type List struct {
+	OfType Type
+}
+
+func (l *List) TypeName() string { return l.OfType.TypeName() }
+func (l *List) TypeKind() string { return "LIST" }
+
+// NonNull represents an `OfType!` wrapping type.
+type NonNull struct {
+	OfType Type
+}
+
+func (n *NonNull) TypeName() string { return n.OfType.TypeName() }
+func (n *NonNull) TypeKind() string { return "NON_NULL" }
+
+// IsInputType returns true if the type can be used as the type of a variable
+// or an input object field: scalars, enums, input objects, and any List/NonNull
+// wrapping one of those.
+func IsInputType(t Type) bool {
+	switch named := unwrapType(t).(type) {
+	case *Scalar, *Enum, *InputObject:
+		_ = named
+		return true
+	default:
+		return false
+	}
+}
+
+// IsOutputType returns true if the type can be used as the type of a field:
+// scalars, objects, interfaces, unions, enums, and any List/NonNull wrapping
+// one of those.
+func IsOutputType(t Type) bool {
+	switch named := unwrapType(t).(type) {
+	case *Scalar, *Object, *Interface, *Union, *Enum:
+		_ = named
+		return true
+	default:
+		return false
+	}
+}
+
+// IsLeafType returns true if the type has no sub-selections, i.e. scalars
+// and enums.
+func IsLeafType(t Type) bool {
+	switch unwrapType(t).(type) {
+	case *Scalar, *Enum:
+		return true
+	default:
+		return false
+	}
+}
+
+// unwrapType strips List/NonNull wrappers to get at the named type underneath.
+func unwrapType(t Type) Type {
+	for {
+		switch v := t.(type) {
+		case *List:
+			t = v.OfType
+		case *NonNull:
+			t = v.OfType
+		default:
+			return t
+		}
+	}
+}
+
+// builtinScalars are always available, even if the loaded schema never
+// defines them explicitly.
+var builtinScalars = []string{"String", "Int", "Float", "Boolean", "ID"}
+
+// Schema holds every type known to a loaded GraphQL schema, keyed by name, along
+// with the names of the root operation types.
+type Schema struct {
+	Types            map[string]Type
+	QueryType        string
+	MutationType     string
+	SubscriptionType string
+}
+
+// LookupField returns the field definition for fieldName on the type named
+// typeName, if both exist.
+func (s *Schema) LookupField(typeName, fieldName string) *FieldDefinition {
+	switch t := s.Types[typeName].(type) {
+	case *Object:
+		return t.Fields[fieldName]
+	case *Interface:
+		return t.Fields[fieldName]
+	default:
+		return nil
+	}
+}
+
+// LoadSchema reads and merges one or more GraphQL SDL files into a Schema.
+func LoadSchema(schemaFiles []string) (*Schema, error) {
+	schema := newSchema()
+
+	for _, schemaFile := range schemaFiles {
+		buf, err := ioutil.ReadFile(schemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema file %s: %w", schemaFile, err)
+		}
+
+		doc, err := parser.Parse(parser.ParseParams{
+			Source: string(buf),
+			Options: parser.ParseOptions{
+				NoLocation: true,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema file %s: %w", schemaFile, err)
+		}
+
+		if err := mergeSchemaDocument(schema, doc); err != nil {
+			return nil, fmt.Errorf("failed to load schema file %s: %w", schemaFile, err)
+		}
+	}
+
+	return schema, nil
+}
+
+// introspectionQuery is the standard introspection query used to fetch a
+// schema's type system over HTTP, as defined by the GraphQL spec.
+const introspectionQuery = `
+	query IntrospectionQuery {
+		__schema {
+			queryType { name }
+			mutationType { name }
+			subscriptionType { name }
+			types {
+				kind
+				name
+				fields(includeDeprecated: true) {
+					name
+					args {
+						name
+						type { ...TypeRef }
+						defaultValue
+					}
+					type { ...TypeRef }
+				}
+				inputFields {
+					name
+					type { ...TypeRef }
+					defaultValue
+				}
+				interfaces { name }
+				enumValues(includeDeprecated: true) { name }
+				possibleTypes { name }
+			}
+		}
+	}
+	fragment TypeRef on __Type {
+		kind
+		name
+		ofType {
+			kind
+			name
+			ofType {
+				kind
+				name
+				ofType {
+					kind
+					name
+				}
+			}
+		}
+	}
+`
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+type introspectionField struct {
+	Name string                  `json:"name"`
+	Type *introspectionTypeRef   `json:"type"`
+	Args []introspectionArgument `json:"args"`
+}
+
+type introspectionArgument struct {
+	Name         string                `json:"name"`
+	Type         *introspectionTypeRef `json:"type"`
+	DefaultValue *string               `json:"defaultValue"`
+}
+
+type introspectionEnumValue struct {
+	Name string `json:"name"`
+}
+
+type introspectionNamedRef struct {
+	Name string `json:"name"`
+}
+
+type introspectionType struct {
+	Kind          string                   `json:"kind"`
+	Name          string                   `json:"name"`
+	Fields        []introspectionField     `json:"fields"`
+	InputFields   []introspectionArgument  `json:"inputFields"`
+	Interfaces    []introspectionNamedRef  `json:"interfaces"`
+	EnumValues    []introspectionEnumValue `json:"enumValues"`
+	PossibleTypes []introspectionNamedRef  `json:"possibleTypes"`
+}
+
+type introspectionResponse struct {
+	Data struct {
+		Schema struct {
+			QueryType        *introspectionNamedRef `json:"queryType"`
+			MutationType     *introspectionNamedRef `json:"mutationType"`
+			SubscriptionType *introspectionNamedRef `json:"subscriptionType"`
+			Types            []introspectionType    `json:"types"`
+		} `json:"__schema"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// LoadSchemaFromEndpoint runs the standard GraphQL introspection query against
+// endpoint and builds a Schema from the response.
+func LoadSchemaFromEndpoint(endpoint string) (*Schema, error) {
+	body, err := json.Marshal(map[string]string{"query": introspectionQuery})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Post(endpoint, "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query introspection endpoint %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	respBuf, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var introspected introspectionResponse
+	if err := json.Unmarshal(respBuf, &introspected); err != nil {
+		return nil, fmt.Errorf("failed to parse introspection response from %s: %w", endpoint, err)
+	}
+	if len(introspected.Errors) > 0 {
+		return nil, fmt.Errorf("introspection endpoint %s returned errors: %s", endpoint, introspected.Errors[0].Message)
+	}
+
+	schema := newSchema()
+	if introspected.Data.Schema.QueryType != nil {
+		schema.QueryType = introspected.Data.Schema.QueryType.Name
+	}
+	if introspected.Data.Schema.MutationType != nil {
+		schema.MutationType = introspected.Data.Schema.MutationType.Name
+	}
+	if introspected.Data.Schema.SubscriptionType != nil {
+		schema.SubscriptionType = introspected.Data.Schema.SubscriptionType.Name
+	}
+
+	// Named types must all exist before fields can reference each other, so
+	// this runs in two passes just like mergeSchemaDocument does.
+	for _, t := range introspected.Data.Schema.Types {
+		switch t.Kind {
+		case "OBJECT":
+			obj := &Object{Name: t.Name, Fields: make(map[string]*FieldDefinition, len(t.Fields))}
+			for _, iface := range t.Interfaces {
+				obj.Interfaces = append(obj.Interfaces, iface.Name)
+			}
+			schema.Types[t.Name] = obj
+		case "INTERFACE":
+			schema.Types[t.Name] = &Interface{Name: t.Name, Fields: make(map[string]*FieldDefinition, len(t.Fields))}
+		case "UNION":
+			union := &Union{Name: t.Name}
+			for _, possible := range t.PossibleTypes {
+				union.Types = append(union.Types, possible.Name)
+			}
+			schema.Types[t.Name] = union
+		case "ENUM":
+			enum := &Enum{Name: t.Name}
+			for _, v := range t.EnumValues {
+				enum.Values = append(enum.Values, v.Name)
+			}
+			schema.Types[t.Name] = enum
+		case "INPUT_OBJECT":
+			schema.Types[t.Name] = &InputObject{Name: t.Name, Fields: make(map[string]*InputFieldDefinition, len(t.InputFields))}
+		case "SCALAR":
+			schema.Types[t.Name] = &Scalar{Name: t.Name}
+		}
+	}
+
+	for _, t := range introspected.Data.Schema.Types {
+		switch t.Kind {
+		case "OBJECT", "INTERFACE":
+			fields := make(map[string]*FieldDefinition, len(t.Fields))
+			for _, f := range t.Fields {
+				fields[f.Name] = &FieldDefinition{
+					Name: f.Name,
+					Type: resolveIntrospectionType(schema, f.Type),
+					Args: resolveIntrospectionArgs(schema, f.Args),
+				}
+			}
+			if obj, ok := schema.Types[t.Name].(*Object); ok {
+				obj.Fields = fields
+			} else if iface, ok := schema.Types[t.Name].(*Interface); ok {
+				iface.Fields = fields
+			}
+
+		case "INPUT_OBJECT":
+			input := schema.Types[t.Name].(*InputObject)
+			for _, f := range t.InputFields {
+				defaultValue := ""
+				if f.DefaultValue != nil {
+					defaultValue = *f.DefaultValue
+				}
+				input.Fields[f.Name] = &InputFieldDefinition{
+					Name:         f.Name,
+					Type:         resolveIntrospectionType(schema, f.Type),
+					DefaultValue: defaultValue,
+				}
+			}
+		}
+	}
+
+	return schema, nil
+}
+
+func resolveIntrospectionArgs(schema *Schema, args []introspectionArgument) []*InputFieldDefinition {
+	if len(args) == 0 {
+		return nil
+	}
+	resolved := make([]*InputFieldDefinition, 0, len(args))
+	for _, arg := range args {
+		defaultValue := ""
+		if arg.DefaultValue != nil {
+			defaultValue = *arg.DefaultValue
+		}
+		resolved = append(resolved, &InputFieldDefinition{
+			Name:         arg.Name,
+			Type:         resolveIntrospectionType(schema, arg.Type),
+			DefaultValue: defaultValue,
+		})
+	}
+	return resolved
+}
+
+func resolveIntrospectionType(schema *Schema, ref *introspectionTypeRef) Type {
+	if ref == nil {
+		return nil
+	}
+	switch ref.Kind {
+	case "NON_NULL":
+		return &NonNull{OfType: resolveIntrospectionType(schema, ref.OfType)}
+	case "LIST":
+		return &List{OfType: resolveIntrospectionType(schema, ref.OfType)}
+	default:
+		return schema.Types[ref.Name]
+	}
+}
+
+func newSchema() *Schema {
+	schema := &Schema{
+		Types:        make(map[string]Type, 100),
+		QueryType:    "Query",
+		MutationType: "Mutation",
+	}
+	for _, scalarName := range builtinScalars {
+		schema.Types[scalarName] = &Scalar{Name: scalarName}
+	}
+	return schema
+}
+
+// mergeSchemaDocument folds the type system definitions found in doc into schema.
+func mergeSchemaDocument(schema *Schema, doc *ast.Document) error {
+	// First pass: register every named type so field types can reference
+	// types defined later in the same document.
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.ObjectDefinition:
+			obj := &Object{Name: d.Name.Value, Fields: make(map[string]*FieldDefinition, len(d.Fields))}
+			for _, iface := range d.Interfaces {
+				obj.Interfaces = append(obj.Interfaces, iface.Name.Value)
+			}
+			schema.Types[d.Name.Value] = obj
+
+		case *ast.InterfaceDefinition:
+			schema.Types[d.Name.Value] = &Interface{Name: d.Name.Value, Fields: make(map[string]*FieldDefinition, len(d.Fields))}
+
+		case *ast.UnionDefinition:
+			union := &Union{Name: d.Name.Value}
+			for _, t := range d.Types {
+				union.Types = append(union.Types, t.Name.Value)
+			}
+			schema.Types[d.Name.Value] = union
+
+		case *ast.EnumDefinition:
+			enum := &Enum{Name: d.Name.Value}
+			for _, v := range d.Values {
+				enum.Values = append(enum.Values, v.Name.Value)
+			}
+			schema.Types[d.Name.Value] = enum
+
+		case *ast.InputObjectDefinition:
+			schema.Types[d.Name.Value] = &InputObject{Name: d.Name.Value, Fields: make(map[string]*InputFieldDefinition, len(d.Fields))}
+
+		case *ast.ScalarDefinition:
+			schema.Types[d.Name.Value] = &Scalar{Name: d.Name.Value}
+
+		case *ast.SchemaDefinition:
+			for _, op := range d.OperationTypes {
+				switch op.Operation {
+				case "query":
+					schema.QueryType = op.Type.Name.Value
+				case "mutation":
+					schema.MutationType = op.Type.Name.Value
+				case "subscription":
+					schema.SubscriptionType = op.Type.Name.Value
+				}
+			}
+		}
+	}
+
+	// Second pass: fields can now resolve named types regardless of definition order.
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.ObjectDefinition:
+			obj := schema.Types[d.Name.Value].(*Object)
+			for _, field := range d.Fields {
+				fieldDef, err := transformFieldDefinition(schema, field)
+				if err != nil {
+					return err
+				}
+				obj.Fields[field.Name.Value] = fieldDef
+			}
+
+		case *ast.InterfaceDefinition:
+			iface := schema.Types[d.Name.Value].(*Interface)
+			for _, field := range d.Fields {
+				fieldDef, err := transformFieldDefinition(schema, field)
+				if err != nil {
+					return err
+				}
+				iface.Fields[field.Name.Value] = fieldDef
+			}
+
+		case *ast.InputObjectDefinition:
+			input := schema.Types[d.Name.Value].(*InputObject)
+			for _, field := range d.Fields {
+				input.Fields[field.Name.Value] = transformInputValueDefinition(schema, field)
+			}
+		}
+	}
+
+	return nil
+}
+
+func transformFieldDefinition(schema *Schema, field *ast.FieldDefinition) (*FieldDefinition, error) {
+	fieldType, err := resolveASTType(schema, field.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldDef := &FieldDefinition{
+		Name: field.Name.Value,
+		Type: fieldType,
+	}
+	for _, arg := range field.Arguments {
+		fieldDef.Args = append(fieldDef.Args, transformInputValueDefinition(schema, arg))
+	}
+	return fieldDef, nil
+}
+
+func transformInputValueDefinition(schema *Schema, def *ast.InputValueDefinition) *InputFieldDefinition {
+	fieldType, err := resolveASTType(schema, def.Type)
+	if err != nil {
+		fieldType = nil
+	}
+
+	input := &InputFieldDefinition{
+		Name: def.Name.Value,
+		Type: fieldType,
+	}
+	if def.DefaultValue != nil {
+		if value, err := transformFieldArgumentValue(def.DefaultValue); err == nil {
+			input.DefaultValue = value
+		}
+	}
+	return input
+}
+
+// resolveASTType resolves a parsed SDL type reference (ast.Named/ast.List/ast.NonNull)
+// to a schema Type, looking up named types in schema.Types.
+func resolveASTType(schema *Schema, def ast.Type) (Type, error) {
+	switch v := def.(type) {
+	case *ast.NonNull:
+		ofType, err := resolveASTType(schema, v.Type)
+		if err != nil {
+			return nil, err
+		}
+		return &NonNull{OfType: ofType}, nil
+
+	case *ast.List:
+		ofType, err := resolveASTType(schema, v.Type)
+		if err != nil {
+			return nil, err
+		}
+		return &List{OfType: ofType}, nil
+
+	case *ast.Named:
+		namedType, ok := schema.Types[v.Name.Value]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q", v.Name.Value)
+		}
+		return namedType, nil
+
+	default:
+		return nil, fmt.Errorf("unknown type kind: %s", def.GetKind())
+	}
+}
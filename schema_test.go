@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempSchema(t *testing.T, contents string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schema.graphql")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write temp schema: %s", err)
+	}
+	return path
+}
+
+func TestLoadSchemaResolvesFieldTypes(t *testing.T) {
+	schemaFile := writeTempSchema(t, `
+		type User {
+			id: ID!
+			name: String
+			friends: [User!]
+		}
+
+		type Query {
+			me: User
+		}
+	`)
+
+	schema, err := LoadSchema([]string{schemaFile})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	meField := schema.LookupField("Query", "me")
+	if meField == nil {
+		t.Fatalf("Expected Query.me to be resolved")
+	}
+	if meField.Type.TypeName() != "User" {
+		t.Errorf("Expected Query.me to resolve to User, got %s", meField.Type.TypeName())
+	}
+
+	idField := schema.LookupField("User", "id")
+	if idField == nil {
+		t.Fatalf("Expected User.id to be resolved")
+	}
+	if !IsLeafType(idField.Type) {
+		t.Errorf("Expected User.id to be a leaf type")
+	}
+
+	friendsField := schema.LookupField("User", "friends")
+	if friendsField == nil {
+		t.Fatalf("Expected User.friends to be resolved")
+	}
+	if _, ok := friendsField.Type.(*List); !ok {
+		t.Errorf("Expected User.friends to be a list type, got %T", friendsField.Type)
+	}
+}
+
+func TestTransformGraphqlWithSchema(t *testing.T) {
+	schemaFile := writeTempSchema(t, `
+		type User {
+			id: ID!
+			name: String
+		}
+
+		type Query {
+			me: User
+		}
+	`)
+
+	schema, err := LoadSchema([]string{schemaFile})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	templateData := TemplateData{}
+	err = transformGraphql(&templateData, schema, `
+		query GetMe {
+			me {
+				id
+				name
+			}
+		}
+	`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(templateData.Queries) != 1 {
+		t.Fatalf("Expected 1 query, got %d", len(templateData.Queries))
+	}
+
+	meField := templateData.Queries[0].Fields[0]
+	if meField.NamedType != "User" {
+		t.Errorf("Expected me field to resolve to User, got %s", meField.NamedType)
+	}
+
+	idField := meField.SubFields[0]
+	if !idField.IsNonNull || !idField.IsLeaf || idField.NamedType != "ID" {
+		t.Errorf("Expected id field to be a non-null leaf ID, got %+v", idField)
+	}
+}
@@ -15,6 +15,29 @@ type FieldArgument struct {
 	// Value is the value of the argument, serialized as a string.
 	// For example, `true`, `"hello"`, or `["a", "b"]`.
 	Value string
+	// FieldType is the resolved type of the argument, as defined by the field's
+	// parent type in the loaded schema. This is nil if no schema was loaded.
+	FieldType Type
+	// NamedType is the name of FieldType with any List/NonNull wrappers stripped.
+	// This is empty if no schema was loaded.
+	NamedType string
+	// IsList is true if FieldType is, or wraps, a list type.
+	IsList bool
+	// IsNonNull is true if FieldType is a non-null type.
+	IsNonNull bool
+	// IsLeaf is true if NamedType has no sub-selections, i.e. a scalar or enum.
+	IsLeaf bool
+}
+
+// Directive represents a directive applied to a field, fragment spread, inline
+// fragment, fragment definition or operation, e.g. `@include(if: $shouldInclude)`.
+// Directives on variable definitions are not captured (see Variable).
+type Directive struct {
+	// Name is the name of the directive, without the leading `@`.
+	Name string
+	// Arguments is a list of all arguments provided to the directive. This is
+	// nil if no arguments are provided.
+	Arguments []FieldArgument
 }
 
 // GraphqlField represents a field selected in a query, mutation, or fragment.
@@ -29,9 +52,35 @@ type GraphqlField struct {
 	// Arguments is a list of all arguments provided to the field. This is nil if no
 	// arguments are provided.
 	Arguments []FieldArgument
+	// Directives is a list of all directives applied to the field. This is nil if
+	// no directives are applied.
+	Directives []Directive
 	// SubFields is a list of all fields selected in the body of the field. This is nil if
 	// no fields are selected.
 	SubFields []GraphqlField
+	// IsConnection is true if this field looks like a Relay connection: its type
+	// name ends in `Connection` with `edges { node }`/`pageInfo` sub-selections,
+	// or it takes any of the standard `first`/`last`/`after`/`before` arguments.
+	IsConnection bool
+	// NodeType is the resolved type name of `edges.node`. This is only populated
+	// if IsConnection is true and a `node` field was actually selected.
+	NodeType string
+	// Nodes projects `edges { node { ... } } ` down to the node's own selected
+	// fields, so templates can iterate nodes directly instead of walking
+	// edges/node themselves. This is only populated if IsConnection is true.
+	Nodes []GraphqlField
+	// FieldType is the resolved type of this field, as defined by its parent type in the
+	// loaded schema. This is nil if no schema was loaded, or the field could not be resolved.
+	FieldType Type
+	// NamedType is the name of FieldType with any List/NonNull wrappers stripped.
+	// This is empty if no schema was loaded.
+	NamedType string
+	// IsList is true if FieldType is, or wraps, a list type.
+	IsList bool
+	// IsNonNull is true if FieldType is a non-null type.
+	IsNonNull bool
+	// IsLeaf is true if NamedType has no sub-selections, i.e. a scalar or enum.
+	IsLeaf bool
 }
 
 // Fragment represents a fragment defined in the document.
@@ -42,19 +91,49 @@ type Fragment struct {
 	SourceType string
 	// Fields is a list of all fields selected in the body of the fragment.
 	Fields []GraphqlField
+	// Directives is a list of all directives applied to the fragment definition
+	// itself. This is nil if no directives are applied.
+	Directives []Directive
 	// FragmentDependencies is a list of all fragments used in the body of the fragment.
 	// This is only the name of the fragment, not the full definition, provided only for
 	// convenience. There is no guarantee that the fragment is defined in the same document.
 	FragmentDependencies []string
+	// OperationText is the canonical form of the fragment, with its own fragment
+	// dependencies inlined in dependency order. This is only populated when the
+	// target's PersistedQueries option is enabled.
+	OperationText string
+	// OperationHash is the SHA-256 hash of OperationText, hex-encoded. This is only
+	// populated when the target's PersistedQueries option is enabled.
+	OperationHash string
 }
 
 // Variable represents a variable accepted by a query or mutation.
+// Variable does not expose directives applied to the variable definition
+// itself (e.g. `$foo: String @deprecated`): the underlying graphql-go parser
+// doesn't parse them, so there is nothing here to populate. Directives on the
+// field/fragment/operation that use the variable are unaffected and still
+// appear on GraphqlField/Fragment/Operation as usual.
 type Variable struct {
 	// Name is the name of the variable, without the leading `$`.
 	Name string
 	// Type is the type of the variable, serialized as a string.
 	// For example, `String!` or `[Int]`.
 	Type string
+	// DefaultValue is the variable's default value, serialized the same way as
+	// FieldArgument.Value. This is empty if no default value is given.
+	DefaultValue string
+	// FieldType is the resolved type of the variable, as defined in the loaded
+	// schema. This is nil if no schema was loaded.
+	FieldType Type
+	// NamedType is the name of FieldType with any List/NonNull wrappers stripped.
+	// This is empty if no schema was loaded.
+	NamedType string
+	// IsList is true if FieldType is, or wraps, a list type.
+	IsList bool
+	// IsNonNull is true if FieldType is a non-null type.
+	IsNonNull bool
+	// IsLeaf is true if NamedType has no sub-selections, i.e. a scalar or enum.
+	IsLeaf bool
 }
 
 // Operation represents a query or mutation.
@@ -64,8 +143,18 @@ type Operation struct {
 	Name string
 	// Variables is a list of all variables accepted by the operation.
 	Variables []Variable
+	// Directives is a list of all directives applied to the operation itself.
+	// This is nil if no directives are applied.
+	Directives []Directive
 	// Fields is a list of all fields selected in the body of the operation.
 	Fields []GraphqlField
+	// OperationText is the canonical form of the operation, with its fragment
+	// dependencies inlined in dependency order. This is only populated when the
+	// target's PersistedQueries option is enabled.
+	OperationText string
+	// OperationHash is the SHA-256 hash of OperationText, hex-encoded. This is only
+	// populated when the target's PersistedQueries option is enabled.
+	OperationHash string
 }
 
 // TemplateData represents the value of `.` given to the template.
@@ -80,6 +169,9 @@ type TemplateData struct {
 	// Mutations is a list of all mutations defined in the document. It is
 	// separated from queries only for convenience.
 	Mutations []Operation
+	// Subscriptions is a list of all subscriptions defined in the document. It
+	// is separated from queries and mutations only for convenience.
+	Subscriptions []Operation
 }
 
 func transformFieldArgumentValue(node ast.Value) (string, error) {
@@ -115,7 +207,7 @@ func transformFieldArgumentValue(node ast.Value) (string, error) {
 			if val {
 				return "true", nil
 			}
-			return "fales", nil
+			return "false", nil
 		case interface{ String() string }:
 			return val.String(), nil
 		}
@@ -138,13 +230,87 @@ func transformFieldArgumentValue(node ast.Value) (string, error) {
 	}
 }
 
-func transformGraphqlField(def *ast.SelectionSet) ([]GraphqlField, error) {
+// annotateType fills in the NamedType/IsList/IsNonNull/IsLeaf fields derived from t.
+// It is a no-op if t is nil, which happens whenever no schema was loaded.
+func annotateType(namedType *string, isList, isNonNull, isLeaf *bool, t Type) {
+	if t == nil {
+		return
+	}
+
+	if _, ok := t.(*NonNull); ok {
+		*isNonNull = true
+	}
+
+	named := t
+	for {
+		switch v := named.(type) {
+		case *NonNull:
+			named = v.OfType
+			continue
+		case *List:
+			*isList = true
+			named = v.OfType
+			continue
+		}
+		break
+	}
+
+	*namedType = named.TypeName()
+	*isLeaf = IsLeafType(t)
+}
+
+func lookupInputField(args []*InputFieldDefinition, name string) *InputFieldDefinition {
+	for _, arg := range args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+func transformDirectives(defs []*ast.Directive) ([]Directive, error) {
+	if len(defs) == 0 {
+		return nil, nil
+	}
+
+	directives := make([]Directive, 0, len(defs))
+	for _, def := range defs {
+		directive := Directive{Name: def.Name.Value}
+		if len(def.Arguments) > 0 {
+			directive.Arguments = make([]FieldArgument, 0, len(def.Arguments))
+			for _, arg := range def.Arguments {
+				value, err := transformFieldArgumentValue(arg.Value)
+				if err != nil {
+					return nil, err
+				}
+				directive.Arguments = append(directive.Arguments, FieldArgument{
+					Name:  arg.Name.Value,
+					Value: value,
+				})
+			}
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+func transformGraphqlField(def *ast.SelectionSet, schema *Schema, parentTypeName string) ([]GraphqlField, error) {
 	fields := make([]GraphqlField, 0, len(def.Selections))
 	for _, selection := range def.Selections {
 		if field, ok := selection.(*ast.Field); ok {
 			transformedField := GraphqlField{
 				Name: field.Name.Value,
 			}
+
+			var fieldDef *FieldDefinition
+			if schema != nil {
+				fieldDef = schema.LookupField(parentTypeName, field.Name.Value)
+				if fieldDef != nil {
+					transformedField.FieldType = fieldDef.Type
+					annotateType(&transformedField.NamedType, &transformedField.IsList, &transformedField.IsNonNull, &transformedField.IsLeaf, fieldDef.Type)
+				}
+			}
+
 			if len(field.Arguments) > 0 {
 				transformedField.Arguments = make([]FieldArgument, 0, len(field.Arguments))
 				for _, arg := range field.Arguments {
@@ -157,24 +323,58 @@ func transformGraphqlField(def *ast.SelectionSet) ([]GraphqlField, error) {
 						Name:  arg.Name.Value,
 						Value: fieldValue,
 					}
+					if fieldDef != nil {
+						if argDef := lookupInputField(fieldDef.Args, arg.Name.Value); argDef != nil {
+							parsedFieldArg.FieldType = argDef.Type
+							annotateType(&parsedFieldArg.NamedType, &parsedFieldArg.IsList, &parsedFieldArg.IsNonNull, &parsedFieldArg.IsLeaf, argDef.Type)
+						}
+					}
 					transformedField.Arguments = append(transformedField.Arguments, parsedFieldArg)
 				}
 			}
+			directives, err := transformDirectives(field.Directives)
+			if err != nil {
+				return nil, err
+			}
+			transformedField.Directives = directives
+
 			if field.SelectionSet != nil {
-				subFields, err := transformGraphqlField(field.SelectionSet)
+				subFields, err := transformGraphqlField(field.SelectionSet, schema, transformedField.NamedType)
 				if err != nil {
 					return nil, err
 				}
 				transformedField.SubFields = subFields
 			}
+
+			if isRelayConnectionField(transformedField) {
+				transformedField.IsConnection = true
+				transformedField.NodeType = connectionNodeType(transformedField)
+				transformedField.Nodes = connectionNodes(transformedField)
+			}
+
 			fields = append(fields, transformedField)
 		} else if fragmentSpread, ok := selection.(*ast.FragmentSpread); ok {
+			directives, err := transformDirectives(fragmentSpread.Directives)
+			if err != nil {
+				return nil, err
+			}
 			fields = append(fields, GraphqlField{
-				IsSpread: true,
-				Name:     fragmentSpread.Name.Value,
+				IsSpread:   true,
+				Name:       fragmentSpread.Name.Value,
+				Directives: directives,
 			})
 		} else if inlineFragment, ok := selection.(*ast.InlineFragment); ok {
-			subFields, err := transformGraphqlField(inlineFragment.SelectionSet)
+			inlineType := parentTypeName
+			if inlineFragment.TypeCondition != nil {
+				inlineType = inlineFragment.TypeCondition.Name.Value
+			}
+
+			subFields, err := transformGraphqlField(inlineFragment.SelectionSet, schema, inlineType)
+			if err != nil {
+				return nil, err
+			}
+
+			directives, err := transformDirectives(inlineFragment.Directives)
 			if err != nil {
 				return nil, err
 			}
@@ -182,7 +382,8 @@ func transformGraphqlField(def *ast.SelectionSet) ([]GraphqlField, error) {
 			fields = append(fields, GraphqlField{
 				IsSpread:   true,
 				Name:       "",
-				SourceType: inlineFragment.TypeCondition.Name.Value,
+				SourceType: inlineType,
+				Directives: directives,
 				SubFields:  subFields,
 			})
 		} else {
@@ -209,8 +410,12 @@ func gatherFragmentDependencies(fields []GraphqlField) []string {
 	return fragmentNames
 }
 
-func transformFragment(def *ast.FragmentDefinition) (Fragment, error) {
-	fields, err := transformGraphqlField(def.SelectionSet)
+func transformFragment(def *ast.FragmentDefinition, schema *Schema) (Fragment, error) {
+	fields, err := transformGraphqlField(def.SelectionSet, schema, def.TypeCondition.Name.Value)
+	if err != nil {
+		return Fragment{}, err
+	}
+	directives, err := transformDirectives(def.Directives)
 	if err != nil {
 		return Fragment{}, err
 	}
@@ -218,6 +423,7 @@ func transformFragment(def *ast.FragmentDefinition) (Fragment, error) {
 		Name:                 def.Name.Value,
 		SourceType:           def.TypeCondition.Name.Value,
 		Fields:               fields,
+		Directives:           directives,
 		FragmentDependencies: gatherFragmentDependencies(fields),
 	}, nil
 }
@@ -237,9 +443,10 @@ func transformVariableType(def ast.Type) (string, error) {
 	return "", fmt.Errorf("Unknown type kind: %s", def.GetKind())
 }
 
-func transformOperation(def *ast.OperationDefinition) (Operation, error) {
-	operation := Operation{
-		Name: def.Name.Value,
+func transformOperation(def *ast.OperationDefinition, schema *Schema, parentTypeName string) (Operation, error) {
+	operation := Operation{}
+	if def.Name != nil {
+		operation.Name = def.Name.Value
 	}
 	if len(def.VariableDefinitions) > 0 {
 		operation.Variables = make([]Variable, 0, len(def.VariableDefinitions))
@@ -249,14 +456,33 @@ func transformOperation(def *ast.OperationDefinition) (Operation, error) {
 				return operation, err
 			}
 
-			operation.Variables = append(operation.Variables, Variable{
+			variable := Variable{
 				Name: varDef.Variable.Name.Value,
 				Type: varType,
-			})
+			}
+			if varDef.DefaultValue != nil {
+				defaultValue, err := transformFieldArgumentValue(varDef.DefaultValue)
+				if err != nil {
+					return operation, err
+				}
+				variable.DefaultValue = defaultValue
+			}
+			if schema != nil {
+				if resolved, err := resolveASTType(schema, varDef.Type); err == nil {
+					variable.FieldType = resolved
+					annotateType(&variable.NamedType, &variable.IsList, &variable.IsNonNull, &variable.IsLeaf, resolved)
+				}
+			}
+			operation.Variables = append(operation.Variables, variable)
 		}
 	}
+	operationDirectives, err := transformDirectives(def.Directives)
+	if err != nil {
+		return operation, err
+	}
+	operation.Directives = operationDirectives
 	if def.SelectionSet != nil {
-		fields, err := transformGraphqlField(def.SelectionSet)
+		fields, err := transformGraphqlField(def.SelectionSet, schema, parentTypeName)
 		if err != nil {
 			return operation, err
 		}
@@ -265,9 +491,9 @@ func transformOperation(def *ast.OperationDefinition) (Operation, error) {
 	return operation, nil
 }
 
-func transformGraphql(templateData *TemplateData, schema string) error {
+func transformGraphql(templateData *TemplateData, schema *Schema, source string) error {
 	doc, err := parser.Parse(parser.ParseParams{
-		Source: schema,
+		Source: source,
 		Options: parser.ParseOptions{
 			NoLocation: true,
 		},
@@ -282,27 +508,47 @@ func transformGraphql(templateData *TemplateData, schema string) error {
 	for _, def := range doc.Definitions {
 		switch def.GetKind() {
 		case "OperationDefinition":
-			switch def.(*ast.OperationDefinition).Operation {
+			opDef := def.(*ast.OperationDefinition)
+			switch opDef.Operation {
 			case "query":
-				query, err := transformOperation(def.(*ast.OperationDefinition))
+				parentType := "Query"
+				if schema != nil {
+					parentType = schema.QueryType
+				}
+				query, err := transformOperation(opDef, schema, parentType)
 				if err != nil {
 					return err
 				}
 				templateData.Queries = append(templateData.Queries, query)
 
 			case "mutation":
-				mutation, err := transformOperation(def.(*ast.OperationDefinition))
+				parentType := "Mutation"
+				if schema != nil {
+					parentType = schema.MutationType
+				}
+				mutation, err := transformOperation(opDef, schema, parentType)
 				if err != nil {
 					return err
 				}
 				templateData.Mutations = append(templateData.Mutations, mutation)
 
+			case "subscription":
+				parentType := "Subscription"
+				if schema != nil {
+					parentType = schema.SubscriptionType
+				}
+				subscription, err := transformOperation(opDef, schema, parentType)
+				if err != nil {
+					return err
+				}
+				templateData.Subscriptions = append(templateData.Subscriptions, subscription)
+
 			default:
-				return fmt.Errorf("Unknown operation kind: %s", def.(*ast.OperationDefinition).Operation)
+				return fmt.Errorf("Unknown operation kind: %s", opDef.Operation)
 			}
 
 		case "FragmentDefinition":
-			frag, err := transformFragment(def.(*ast.FragmentDefinition))
+			frag, err := transformFragment(def.(*ast.FragmentDefinition), schema)
 			if err != nil {
 				return err
 			}
@@ -114,7 +114,7 @@ func TestTransformGraphqlFragments(t *testing.T) {
 		},
 	} {
 		actual := TemplateData{}
-		err := transformGraphql(&actual, testCase.input)
+		err := transformGraphql(&actual, nil, testCase.input)
 		if err != nil {
 			t.Errorf("Unexpected error: %s", err)
 			return
@@ -136,3 +136,124 @@ func TestTransformGraphqlFragments(t *testing.T) {
 		}
 	}
 }
+
+func TestTransformGraphqlSubscriptionsDirectivesAndDefaults(t *testing.T) {
+	actual := TemplateData{}
+	err := transformGraphql(&actual, nil, `
+		subscription OnUserUpdated($id: ID, $verbose: Boolean = false) {
+			userUpdated(id: $id) @include(if: $verbose) {
+				id
+				name @deprecated(reason: "use fullName instead")
+			}
+		}
+	`)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+
+	expected := TemplateData{
+		Subscriptions: []Operation{
+			{
+				Name: "OnUserUpdated",
+				Variables: []Variable{
+					{Name: "id", Type: "ID"},
+					{Name: "verbose", Type: "Boolean", DefaultValue: "false"},
+				},
+				Fields: []GraphqlField{
+					{
+						Name: "userUpdated",
+						Arguments: []FieldArgument{
+							{Name: "id", Value: "$id"},
+						},
+						Directives: []Directive{
+							{
+								Name: "include",
+								Arguments: []FieldArgument{
+									{Name: "if", Value: "$verbose"},
+								},
+							},
+						},
+						SubFields: []GraphqlField{
+							{Name: "id"},
+							{
+								Name: "name",
+								Directives: []Directive{
+									{
+										Name: "deprecated",
+										Arguments: []FieldArgument{
+											{Name: "reason", Value: "use fullName instead"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	leftJSON, err := json.MarshalIndent(expected, "", "\t")
+	if err != nil {
+		t.Errorf("Unexpected error parsing expected: %s", err)
+		return
+	}
+	rightJSON, err := json.MarshalIndent(actual, "", "\t")
+	if err != nil {
+		t.Errorf("Unexpected error parsing actual: %s", err)
+		return
+	}
+
+	if string(leftJSON) != string(rightJSON) {
+		t.Errorf("Failed to transform\n\t%s", diffStrings(string(leftJSON), string(rightJSON)))
+	}
+}
+
+func TestTransformGraphqlHandlesTypelessInlineFragment(t *testing.T) {
+	actual := TemplateData{}
+	err := transformGraphql(&actual, nil, `
+		query GetHero {
+			hero {
+				... @include(if: true) {
+					name
+				}
+			}
+		}
+	`)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+
+	heroField := actual.Queries[0].Fields[0]
+	inlineFragment := heroField.SubFields[0]
+	if inlineFragment.SourceType != "" {
+		t.Errorf("Expected a type-condition-less inline fragment to fall back to an empty SourceType, got %q", inlineFragment.SourceType)
+	}
+	if len(inlineFragment.SubFields) != 1 || inlineFragment.SubFields[0].Name != "name" {
+		t.Errorf("Expected inline fragment's sub-selection to still be transformed, got %+v", inlineFragment.SubFields)
+	}
+}
+
+func TestTransformGraphqlHandlesAnonymousOperation(t *testing.T) {
+	actual := TemplateData{}
+	err := transformGraphql(&actual, nil, `
+		{
+			hero {
+				name
+			}
+		}
+	`)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+
+	if len(actual.Queries) != 1 {
+		t.Fatalf("Expected 1 query, got %d", len(actual.Queries))
+	}
+	if actual.Queries[0].Name != "" {
+		t.Errorf("Expected anonymous operation to have an empty Name, got %q", actual.Queries[0].Name)
+	}
+}
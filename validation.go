@@ -0,0 +1,576 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// ValidationError is a single problem found while checking a document against
+// the loaded schema, with enough position information to point a user at the
+// exact file, line and column.
+type ValidationError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// String formats the error the way a compiler would: `file:line:col: message`.
+func (e ValidationError) String() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+type parsedDocument struct {
+	file string
+	doc  *ast.Document
+}
+
+// validateDocuments parses every (path -> source) pair in files, cross-checks
+// fragment spreads between them, and validates field/argument/variable usage
+// against schema. It returns every error found, sorted by file then position;
+// an empty result means the documents are safe to build.
+func validateDocuments(schema *Schema, files map[string]string) []ValidationError {
+	var errs []ValidationError
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	parsedDocs := make([]parsedDocument, 0, len(files))
+	fragmentsByName := make(map[string]*ast.FragmentDefinition)
+	fragmentFile := make(map[string]string)
+	operationFile := make(map[string]string)
+
+	for _, path := range paths {
+		doc, err := parser.Parse(parser.ParseParams{
+			Source: files[path],
+			Options: parser.ParseOptions{
+				NoLocation: false,
+			},
+		})
+		if err != nil {
+			errs = append(errs, ValidationError{File: path, Message: err.Error()})
+			continue
+		}
+		parsedDocs = append(parsedDocs, parsedDocument{file: path, doc: doc})
+
+		for _, def := range doc.Definitions {
+			switch d := def.(type) {
+			case *ast.FragmentDefinition:
+				if existingFile, ok := fragmentFile[d.Name.Value]; ok {
+					errs = append(errs, newValidationError(path, d, fmt.Sprintf("duplicate fragment %q (also defined in %s)", d.Name.Value, existingFile)))
+					continue
+				}
+				fragmentsByName[d.Name.Value] = d
+				fragmentFile[d.Name.Value] = path
+
+			case *ast.OperationDefinition:
+				if d.Name == nil || d.Name.Value == "" {
+					continue
+				}
+				if existingFile, ok := operationFile[d.Name.Value]; ok {
+					errs = append(errs, newValidationError(path, d, fmt.Sprintf("duplicate operation %q (also defined in %s)", d.Name.Value, existingFile)))
+					continue
+				}
+				operationFile[d.Name.Value] = path
+			}
+		}
+	}
+
+	fragmentNames := make([]string, 0, len(fragmentsByName))
+	for name := range fragmentsByName {
+		fragmentNames = append(fragmentNames, name)
+	}
+
+	for _, pd := range parsedDocs {
+		for _, def := range pd.doc.Definitions {
+			switch d := def.(type) {
+			case *ast.OperationDefinition:
+				parentType := ""
+				if schema != nil {
+					switch d.Operation {
+					case "query":
+						parentType = schema.QueryType
+					case "mutation":
+						parentType = schema.MutationType
+					case "subscription":
+						parentType = schema.SubscriptionType
+					}
+				}
+
+				requiredVars := make(map[string]bool, len(d.VariableDefinitions))
+				variableTypes := make(map[string]variableInfo, len(d.VariableDefinitions))
+				for _, varDef := range d.VariableDefinitions {
+					if _, ok := varDef.Type.(*ast.NonNull); ok && varDef.DefaultValue == nil {
+						requiredVars[varDef.Variable.Name.Value] = true
+					}
+
+					if schema == nil {
+						continue
+					}
+					varType, err := resolveASTType(schema, varDef.Type)
+					if err != nil {
+						errs = append(errs, newValidationError(pd.file, varDef, fmt.Sprintf("variable $%s has unknown type: %s", varDef.Variable.Name.Value, err.Error())))
+						continue
+					}
+					if !IsInputType(varType) {
+						errs = append(errs, newValidationError(pd.file, varDef, fmt.Sprintf("variable $%s has type %q which is not a valid input type", varDef.Variable.Name.Value, describeType(varType))))
+						continue
+					}
+					variableTypes[varDef.Variable.Name.Value] = variableInfo{Type: varType, HasDefault: varDef.DefaultValue != nil}
+				}
+
+				usedVars := make(map[string]bool)
+				collectDirectiveVariableUsage(d.Directives, usedVars)
+				errs = append(errs, validateSelectionSet(schema, pd.file, parentType, d.SelectionSet, fragmentsByName, fragmentNames, usedVars, map[string]bool{}, variableTypes)...)
+
+				for name := range requiredVars {
+					if !usedVars[name] {
+						errs = append(errs, newValidationError(pd.file, d, fmt.Sprintf("variable $%s is declared but never used", name)))
+					}
+				}
+
+			case *ast.FragmentDefinition:
+				parentType := d.TypeCondition.Name.Value
+				if schema != nil {
+					if _, ok := schema.Types[parentType]; !ok {
+						errs = append(errs, newValidationError(pd.file, d, fmt.Sprintf("unknown type %q in fragment %q%s", parentType, d.Name.Value, suggestName(parentType, schemaTypeNames(schema)))))
+						continue
+					}
+				}
+				errs = append(errs, validateSelectionSet(schema, pd.file, parentType, d.SelectionSet, fragmentsByName, fragmentNames, map[string]bool{}, map[string]bool{d.Name.Value: true}, map[string]variableInfo{})...)
+			}
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].File != errs[j].File {
+			return errs[i].File < errs[j].File
+		}
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+
+	return errs
+}
+
+// variableInfo is what validateSelectionSet knows about a declared variable:
+// its resolved schema type, and whether it has a default value (which lets it
+// satisfy a non-null argument even though the variable itself is nullable).
+type variableInfo struct {
+	Type       Type
+	HasDefault bool
+}
+
+// validateSelectionSet walks selectionSet, checking every field and fragment
+// spread against schema (when non-nil), recording every variable referenced
+// in usedVars, checking argument values against variableTypes, and refusing
+// to re-enter a fragment already in fragmentStack.
+func validateSelectionSet(
+	schema *Schema,
+	file string,
+	parentTypeName string,
+	selectionSet *ast.SelectionSet,
+	fragmentsByName map[string]*ast.FragmentDefinition,
+	fragmentNames []string,
+	usedVars map[string]bool,
+	fragmentStack map[string]bool,
+	variableTypes map[string]variableInfo,
+) []ValidationError {
+	if selectionSet == nil {
+		return nil
+	}
+
+	var errs []ValidationError
+
+	for _, selection := range selectionSet.Selections {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			var fieldDef *FieldDefinition
+			if schema != nil && parentTypeName != "" && sel.Name.Value != "__typename" {
+				fieldDef = schema.LookupField(parentTypeName, sel.Name.Value)
+				if fieldDef == nil {
+					errs = append(errs, newValidationError(file, sel, fmt.Sprintf("unknown field %q on type %q%s", sel.Name.Value, parentTypeName, suggestName(sel.Name.Value, fieldNames(schema, parentTypeName)))))
+				}
+			}
+
+			for _, arg := range sel.Arguments {
+				var inputDef *InputFieldDefinition
+				if fieldDef != nil {
+					inputDef = lookupInputField(fieldDef.Args, arg.Name.Value)
+					if inputDef == nil {
+						errs = append(errs, newValidationError(file, arg, fmt.Sprintf("unknown argument %q on field %q%s", arg.Name.Value, sel.Name.Value, suggestName(arg.Name.Value, argNames(fieldDef.Args)))))
+					}
+				}
+				if inputDef != nil {
+					errs = append(errs, validateArgumentValue(file, arg, arg.Name.Value, arg.Value, inputDef.Type, variableTypes)...)
+				}
+				collectVariableUsage(arg.Value, usedVars)
+			}
+			collectDirectiveVariableUsage(sel.Directives, usedVars)
+
+			subParentType := ""
+			if fieldDef != nil && fieldDef.Type != nil {
+				subParentType = unwrapType(fieldDef.Type).TypeName()
+			}
+			errs = append(errs, validateSelectionSet(schema, file, subParentType, sel.SelectionSet, fragmentsByName, fragmentNames, usedVars, fragmentStack, variableTypes)...)
+
+		case *ast.FragmentSpread:
+			frag, ok := fragmentsByName[sel.Name.Value]
+			if !ok {
+				errs = append(errs, newValidationError(file, sel, fmt.Sprintf("unknown fragment %q%s", sel.Name.Value, suggestName(sel.Name.Value, fragmentNames))))
+				continue
+			}
+			if fragmentStack[sel.Name.Value] {
+				errs = append(errs, newValidationError(file, sel, fmt.Sprintf("fragment %q is involved in a cyclic spread", sel.Name.Value)))
+				continue
+			}
+			if schema != nil && parentTypeName != "" && !typesAssignable(schema, frag.TypeCondition.Name.Value, parentTypeName) {
+				errs = append(errs, newValidationError(file, sel, fmt.Sprintf("fragment %q on type %q cannot be spread on type %q", sel.Name.Value, frag.TypeCondition.Name.Value, parentTypeName)))
+			}
+			collectDirectiveVariableUsage(sel.Directives, usedVars)
+
+			nextStack := make(map[string]bool, len(fragmentStack)+1)
+			for name := range fragmentStack {
+				nextStack[name] = true
+			}
+			nextStack[sel.Name.Value] = true
+			errs = append(errs, validateSelectionSet(schema, file, frag.TypeCondition.Name.Value, frag.SelectionSet, fragmentsByName, fragmentNames, usedVars, nextStack, variableTypes)...)
+
+		case *ast.InlineFragment:
+			inlineParentType := parentTypeName
+			if sel.TypeCondition != nil {
+				inlineParentType = sel.TypeCondition.Name.Value
+			}
+			collectDirectiveVariableUsage(sel.Directives, usedVars)
+			errs = append(errs, validateSelectionSet(schema, file, inlineParentType, sel.SelectionSet, fragmentsByName, fragmentNames, usedVars, fragmentStack, variableTypes)...)
+		}
+	}
+
+	return errs
+}
+
+// typesAssignable returns true if a fragment declared `on fragType` may be
+// spread on a selection of type parentType: they're the same type, or one is
+// an interface/union that the other implements/belongs to.
+func typesAssignable(schema *Schema, fragType, parentType string) bool {
+	if fragType == parentType {
+		return true
+	}
+
+	if objectImplements(schema, parentType, fragType) || objectImplements(schema, fragType, parentType) {
+		return true
+	}
+
+	if union, ok := schema.Types[fragType].(*Union); ok {
+		for _, name := range union.Types {
+			if name == parentType {
+				return true
+			}
+		}
+	}
+	if union, ok := schema.Types[parentType].(*Union); ok {
+		for _, name := range union.Types {
+			if name == fragType {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func objectImplements(schema *Schema, objectName, interfaceName string) bool {
+	obj, ok := schema.Types[objectName].(*Object)
+	if !ok {
+		return false
+	}
+	for _, iface := range obj.Interfaces {
+		if iface == interfaceName {
+			return true
+		}
+	}
+	return false
+}
+
+// collectVariableUsage walks a (possibly nested) argument value, marking
+// every variable it references as used.
+func collectVariableUsage(value ast.Value, usedVars map[string]bool) {
+	if value == nil {
+		return
+	}
+
+	switch value.GetKind() {
+	case "Variable":
+		usedVars[value.GetValue().(*ast.Name).Value] = true
+
+	case "ListValue":
+		for _, item := range value.GetValue().([]ast.Value) {
+			collectVariableUsage(item, usedVars)
+		}
+
+	case "ObjectValue":
+		for _, field := range value.GetValue().([]*ast.ObjectField) {
+			collectVariableUsage(field.Value, usedVars)
+		}
+	}
+}
+
+// collectDirectiveVariableUsage walks a list of directives (e.g. `@include(if:
+// $x)`), marking every variable referenced in their arguments as used.
+func collectDirectiveVariableUsage(directives []*ast.Directive, usedVars map[string]bool) {
+	for _, directive := range directives {
+		for _, arg := range directive.Arguments {
+			collectVariableUsage(arg.Value, usedVars)
+		}
+	}
+}
+
+// validateArgumentValue checks that value, passed for argName which expects
+// expectedType, is actually usable there: a variable of an incompatible type,
+// or a literal of the wrong kind (e.g. a string where an Int is expected).
+func validateArgumentValue(file string, node ast.Node, argName string, value ast.Value, expectedType Type, variableTypes map[string]variableInfo) []ValidationError {
+	if expectedType == nil || value == nil || value.GetKind() == "NullValue" {
+		return nil
+	}
+
+	if value.GetKind() == "Variable" {
+		varName := value.GetValue().(*ast.Name).Value
+		info, ok := variableTypes[varName]
+		if !ok || info.Type == nil {
+			return nil
+		}
+		if !variableTypeCompatible(info, expectedType) {
+			return []ValidationError{newValidationError(file, node, fmt.Sprintf("variable $%s of type %q cannot be used for argument %q of type %q", varName, describeType(info.Type), argName, describeType(expectedType)))}
+		}
+		return nil
+	}
+
+	if !literalMatchesType(value, expectedType) {
+		return []ValidationError{newValidationError(file, node, fmt.Sprintf("argument %q expects type %q, got %s", argName, describeType(expectedType), value.GetKind()))}
+	}
+	return nil
+}
+
+// variableTypeCompatible reports whether a variable described by info may be
+// used at a location expecting expectedType, following the same rule the
+// GraphQL spec uses: wrapping types must line up, except that a nullable
+// variable with a default value may still satisfy a non-null location.
+func variableTypeCompatible(info variableInfo, expectedType Type) bool {
+	varType := info.Type
+	hasDefault := info.HasDefault
+
+	for {
+		if expectedNonNull, ok := expectedType.(*NonNull); ok {
+			varNonNull, varIsNonNull := varType.(*NonNull)
+			if !varIsNonNull && !hasDefault {
+				return false
+			}
+			expectedType = expectedNonNull.OfType
+			if varIsNonNull {
+				varType = varNonNull.OfType
+			}
+			hasDefault = false
+			continue
+		}
+
+		if varNonNull, ok := varType.(*NonNull); ok {
+			varType = varNonNull.OfType
+			continue
+		}
+
+		expectedList, expectedIsList := expectedType.(*List)
+		varList, varIsList := varType.(*List)
+		if expectedIsList != varIsList {
+			return false
+		}
+		if expectedIsList {
+			expectedType = expectedList.OfType
+			varType = varList.OfType
+			continue
+		}
+
+		return expectedType.TypeName() == varType.TypeName()
+	}
+}
+
+// literalMatchesType reports whether a literal value (not a variable) is of a
+// kind that can satisfy expectedType: numbers for Int/Float, strings for
+// String/ID, known members for enums, and so on. Custom scalars accept
+// anything, since their valid literal shape isn't known to this tool.
+func literalMatchesType(value ast.Value, expectedType Type) bool {
+	switch t := expectedType.(type) {
+	case *NonNull:
+		return literalMatchesType(value, t.OfType)
+
+	case *List:
+		if value.GetKind() != "ListValue" {
+			return literalMatchesType(value, t.OfType)
+		}
+		for _, item := range value.GetValue().([]ast.Value) {
+			if !literalMatchesType(item, t.OfType) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if value.GetKind() == "Variable" {
+		return true
+	}
+
+	switch t := expectedType.(type) {
+	case *Scalar:
+		switch t.Name {
+		case "Int":
+			return value.GetKind() == "IntValue"
+		case "Float":
+			return value.GetKind() == "IntValue" || value.GetKind() == "FloatValue"
+		case "Boolean":
+			return value.GetKind() == "BooleanValue"
+		case "String":
+			return value.GetKind() == "StringValue"
+		case "ID":
+			return value.GetKind() == "StringValue" || value.GetKind() == "IntValue"
+		default:
+			return true
+		}
+
+	case *Enum:
+		if value.GetKind() != "EnumValue" {
+			return false
+		}
+		for _, v := range t.Values {
+			if v == value.GetValue().(string) {
+				return true
+			}
+		}
+		return false
+
+	case *InputObject:
+		return value.GetKind() == "ObjectValue"
+
+	default:
+		return true
+	}
+}
+
+// describeType renders a schema Type back into SDL syntax, e.g. `[String!]!`.
+func describeType(t Type) string {
+	switch v := t.(type) {
+	case *NonNull:
+		return describeType(v.OfType) + "!"
+	case *List:
+		return "[" + describeType(v.OfType) + "]"
+	default:
+		return t.TypeName()
+	}
+}
+
+func fieldNames(schema *Schema, typeName string) []string {
+	var fields map[string]*FieldDefinition
+	switch t := schema.Types[typeName].(type) {
+	case *Object:
+		fields = t.Fields
+	case *Interface:
+		fields = t.Fields
+	default:
+		return nil
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	return names
+}
+
+func argNames(args []*InputFieldDefinition) []string {
+	names := make([]string, 0, len(args))
+	for _, arg := range args {
+		names = append(names, arg.Name)
+	}
+	return names
+}
+
+func schemaTypeNames(schema *Schema) []string {
+	names := make([]string, 0, len(schema.Types))
+	for name := range schema.Types {
+		names = append(names, name)
+	}
+	return names
+}
+
+// newValidationError builds a ValidationError from node's source position,
+// falling back to a position-less error if node carries no location (e.g.
+// the document was parsed with NoLocation).
+func newValidationError(file string, node ast.Node, message string) ValidationError {
+	loc := node.GetLoc()
+	if loc == nil || loc.Source == nil {
+		return ValidationError{File: file, Message: message}
+	}
+	pos := location.GetLocation(loc.Source, loc.Start)
+	return ValidationError{File: file, Line: pos.Line, Column: pos.Column, Message: message}
+}
+
+// suggestName returns a " (did you mean \"X\"?)" hint for the closest match
+// to target among candidates, or "" if nothing is close enough to be useful.
+func suggestName(target string, candidates []string) string {
+	best := ""
+	bestDistance := -1
+
+	for _, candidate := range candidates {
+		distance := levenshteinDistance(target, candidate)
+		if bestDistance == -1 || distance < bestDistance {
+			bestDistance = distance
+			best = candidate
+		}
+	}
+
+	if best == "" || bestDistance > 3 {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+func levenshteinDistance(a, b string) int {
+	aRunes, bRunes := []rune(a), []rune(b)
+
+	prevRow := make([]int, len(bRunes)+1)
+	currRow := make([]int, len(bRunes)+1)
+	for j := range prevRow {
+		prevRow[j] = j
+	}
+
+	for i := 1; i <= len(aRunes); i++ {
+		currRow[0] = i
+		for j := 1; j <= len(bRunes); j++ {
+			cost := 1
+			if aRunes[i-1] == bRunes[j-1] {
+				cost = 0
+			}
+			currRow[j] = minInt(prevRow[j]+1, minInt(currRow[j-1]+1, prevRow[j-1]+cost))
+		}
+		prevRow, currRow = currRow, prevRow
+	}
+
+	return prevRow[len(bRunes)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,157 @@
+package main
+
+import "testing"
+
+func TestValidateDocumentsCatchesUnknownField(t *testing.T) {
+	schemaFile := writeTempSchema(t, `
+		type User {
+			id: ID!
+			name: String
+		}
+
+		type Query {
+			me: User
+		}
+	`)
+
+	schema, err := LoadSchema([]string{schemaFile})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	errs := validateDocuments(schema, map[string]string{
+		"query.graphql": `
+			query GetMe {
+				me {
+					id
+					nmae
+				}
+			}
+		`,
+	})
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 validation error, got %d: %v", len(errs), errs)
+	}
+	if want := `unknown field "nmae" on type "User" (did you mean "name"?)`; errs[0].Message != want {
+		t.Errorf("Expected message %q, got %q", want, errs[0].Message)
+	}
+}
+
+func TestValidateDocumentsCatchesUnknownFragmentSpread(t *testing.T) {
+	errs := validateDocuments(nil, map[string]string{
+		"query.graphql": `
+			query GetMe {
+				me {
+					... UserFieldz
+				}
+			}
+		`,
+	})
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 validation error, got %d: %v", len(errs), errs)
+	}
+	if want := `unknown fragment "UserFieldz"`; errs[0].Message != want {
+		t.Errorf("Expected message %q, got %q", want, errs[0].Message)
+	}
+}
+
+func TestValidateDocumentsCatchesUnusedRequiredVariable(t *testing.T) {
+	errs := validateDocuments(nil, map[string]string{
+		"query.graphql": `
+			query GetUser($id: ID!) {
+				me {
+					id
+				}
+			}
+		`,
+	})
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 validation error, got %d: %v", len(errs), errs)
+	}
+	if want := `variable $id is declared but never used`; errs[0].Message != want {
+		t.Errorf("Expected message %q, got %q", want, errs[0].Message)
+	}
+}
+
+func TestValidateDocumentsCatchesWrongVariableType(t *testing.T) {
+	schemaFile := writeTempSchema(t, `
+		type User {
+			id: ID!
+			name(limit: Int): String
+		}
+
+		type Query {
+			me: User
+		}
+	`)
+
+	schema, err := LoadSchema([]string{schemaFile})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	errs := validateDocuments(schema, map[string]string{
+		"query.graphql": `
+			query GetMe($limit: String) {
+				me {
+					name(limit: $limit)
+				}
+			}
+		`,
+	})
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 validation error, got %d: %v", len(errs), errs)
+	}
+	if want := `variable $limit of type "String" cannot be used for argument "limit" of type "Int"`; errs[0].Message != want {
+		t.Errorf("Expected message %q, got %q", want, errs[0].Message)
+	}
+}
+
+func TestValidateDocumentsCatchesMisusedArgumentLiteral(t *testing.T) {
+	schemaFile := writeTempSchema(t, `
+		type User {
+			id: ID!
+			name(limit: Int): String
+		}
+
+		type Query {
+			me: User
+		}
+	`)
+
+	schema, err := LoadSchema([]string{schemaFile})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	errs := validateDocuments(schema, map[string]string{
+		"query.graphql": `
+			query GetMe {
+				me {
+					name(limit: "five")
+				}
+			}
+		`,
+	})
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 validation error, got %d: %v", len(errs), errs)
+	}
+	if want := `argument "limit" expects type "Int", got StringValue`; errs[0].Message != want {
+		t.Errorf("Expected message %q, got %q", want, errs[0].Message)
+	}
+}
+
+func TestValidateDocumentsPassesCleanDocument(t *testing.T) {
+	errs := validateDocuments(nil, map[string]string{
+		"query.graphql": `
+			query GetMe {
+				me {
+					id
+				}
+			}
+		`,
+	})
+	if len(errs) != 0 {
+		t.Errorf("Expected no validation errors, got %v", errs)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// targetInputFiles returns every file a target's output depends on: its
+// resolved schema documents, its resolved SDL schema files, and its template.
+func targetInputFiles(target configTarget) ([]string, error) {
+	schemaFiles, err := resolveGlobFiles(target.SchemaFile)
+	if err != nil {
+		return nil, err
+	}
+
+	sdlFiles, err := resolveGlobFiles(target.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(schemaFiles)+len(sdlFiles)+1)
+	files = append(files, schemaFiles...)
+	files = append(files, sdlFiles...)
+	files = append(files, target.TemplateFile)
+	return files, nil
+}
+
+// targetCacheKey hashes the mtimes of every one of a target's input files, so
+// a watch-triggered rebuild can skip targets whose inputs haven't actually
+// changed since they were last built.
+func targetCacheKey(target configTarget) (string, error) {
+	files, err := targetInputFiles(target)
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	hash := sha256.New()
+	for _, filePath := range files {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "%s:%d\n", filePath, info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// watchTargets watches every resolved schema file, template file and
+// configPath itself, rebuilding only the targets whose cache key actually
+// changed on each filesystem event. It blocks until the watcher's event
+// channel is closed; watcher errors are printed to stderr and do not stop it.
+func watchTargets(configPath string, targets []configTarget) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(configPath); err != nil {
+		return err
+	}
+
+	cache := make(map[int]string, len(targets))
+	if err := watchTargetFiles(watcher, targets, cache); err != nil {
+		return err
+	}
+
+	go func() {
+		for watchErr := range watcher.Errors {
+			fmt.Fprintf(os.Stderr, "Watch error: %s\n", watchErr.Error())
+		}
+	}()
+
+	fmt.Println("\nWatching for changes...")
+
+	for event := range watcher.Events {
+		if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+			continue
+		}
+
+		if filepath.Clean(event.Name) == filepath.Clean(configPath) {
+			fmt.Println("\ngraphql-transform.json changed; reloading config")
+
+			conf, err := loadConfig(configPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to reload config: %s\n", err.Error())
+				continue
+			}
+			targets = conf.Targets
+
+			cache = make(map[int]string, len(targets))
+			if err := watchTargetFiles(watcher, targets, cache); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to watch reloaded targets: %s\n", err.Error())
+				continue
+			}
+
+			for index, target := range targets {
+				if err := buildTargets(target); err != nil {
+					fmt.Fprintf(os.Stderr, "Building target [%d] using config %v failed: %s\n", index, target, err.Error())
+				}
+			}
+			continue
+		}
+
+		for index, target := range targets {
+			key, err := targetCacheKey(target)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to compute cache key for target [%d]: %s\n", index, err.Error())
+				continue
+			}
+			if key == cache[index] {
+				continue
+			}
+			cache[index] = key
+
+			fmt.Printf("\n%s changed\n", event.Name)
+			if err := buildTargets(target); err != nil {
+				fmt.Fprintf(os.Stderr, "Building target [%d] using config %v failed: %s\n", index, target, err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// watchTargetFiles registers a filesystem watch on every input file of every
+// target and seeds cache with each target's current cache key. Re-adding a
+// path fsnotify is already watching is a harmless no-op, so this is also used
+// to pick up a target's files again after the config is reloaded.
+func watchTargetFiles(watcher *fsnotify.Watcher, targets []configTarget, cache map[int]string) error {
+	for index, target := range targets {
+		files, err := targetInputFiles(target)
+		if err != nil {
+			return err
+		}
+		for _, filePath := range files {
+			if err := watcher.Add(filePath); err != nil {
+				return err
+			}
+		}
+
+		key, err := targetCacheKey(target)
+		if err != nil {
+			return err
+		}
+		cache[index] = key
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTargetCacheKeyChangesOnMtime(t *testing.T) {
+	dir := t.TempDir()
+
+	schemaPath := filepath.Join(dir, "query.graphql")
+	if err := os.WriteFile(schemaPath, []byte("query Foo { foo }"), 0644); err != nil {
+		t.Fatalf("Failed to write schema file: %s", err)
+	}
+
+	templatePath := filepath.Join(dir, "template.tmpl")
+	if err := os.WriteFile(templatePath, []byte("{{ .Queries }}"), 0644); err != nil {
+		t.Fatalf("Failed to write template file: %s", err)
+	}
+
+	target := configTarget{
+		SchemaFile:   []string{schemaPath},
+		TemplateFile: templatePath,
+	}
+
+	firstKey, err := targetCacheKey(target)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	secondKey, err := targetCacheKey(target)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if firstKey != secondKey {
+		t.Errorf("Expected cache key to be stable across calls with no changes")
+	}
+
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(schemaPath, future, future); err != nil {
+		t.Fatalf("Failed to touch schema file: %s", err)
+	}
+
+	thirdKey, err := targetCacheKey(target)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if thirdKey == firstKey {
+		t.Errorf("Expected cache key to change after a schema file's mtime changed")
+	}
+}